@@ -0,0 +1,782 @@
+// Package periodicarray builds a periodic-array-of-dislocations LAMMPS data
+// file: a single straight dislocation inserted into an otherwise-perfect
+// crystal under fully periodic boundary conditions, with a compensating
+// image-dipole shift so the net Burgers content across the periodic image is
+// zero. The displacement field uses anisotropic-elasticity Stroh formalism
+// when elastic constants are available (e.g. from elastic/sweep), falling
+// back to isotropic Volterra otherwise. See the NIST iprPy
+// dislocation_periodic_array calculation for the reference workflow this
+// mirrors.
+package periodicarray
+
+import (
+	"fmt"
+	"math"
+	"math/cmplx"
+	"os"
+)
+
+// Vec3 is a Cartesian vector or Miller direction, not yet normalized.
+type Vec3 [3]float64
+
+// SlipSystem is the Burgers vector, slip plane normal, and line direction
+// defining the dislocation to insert. Plane and Line need not be normalized;
+// Burgers is in the same length units as the crystal structure.
+type SlipSystem struct {
+	Burgers Vec3
+	Plane   Vec3 // slip plane normal
+	Line    Vec3 // dislocation line direction
+}
+
+// BoxSize is the generated supercell's extent along (b, n, xi): the in-plane
+// glide direction (b), the slip-plane normal (n), and the line direction
+// (xi), expressed as a repeat count of the input unit cell along each axis.
+// Replication walks cell.Box[0]/[1]/[2] directly, so this only lines up with
+// (b, n, xi) when cell.Box is already oriented that way; see UnitCell.
+type BoxSize struct {
+	NB, NN, NXi int
+}
+
+// Options configures BuildPeriodicArray.
+type Options struct {
+	// ElasticConstants is the 6x6 Voigt stiffness tensor in the crystal's
+	// Cartesian frame (GPa). If nil, the isotropic Volterra field is used
+	// instead of the Stroh formalism.
+	ElasticConstants *[6][6]float64
+	// PoissonRatio is used by the isotropic fallback; ShearModulus is passed
+	// to it as well, but the isotropic displacement field does not depend on
+	// it. Both are ignored when ElasticConstants is set.
+	ShearModulus float64
+	PoissonRatio float64
+	// OutDir is where the generated data file and .mod files are written.
+	OutDir string
+	// Masses gives the per-type atomic mass, indexed from type 1 (Masses[0]
+	// is the mass of type 1). Required so the generated data file can
+	// declare "N atom types" and potential.mod a mass command per type;
+	// LAMMPS refuses to run atom_style atomic without both.
+	Masses []float64
+}
+
+// Result is the generated dislocation cell and companion LAMMPS inputs.
+type Result struct {
+	DataFile     string
+	InitMod      string
+	PotentialMod string
+	RelaxMod     string
+}
+
+// Atom is one site of the input perfect-crystal unit cell, in fractional
+// coordinates.
+type Atom struct {
+	Type int
+	Frac Vec3
+}
+
+// UnitCell is the perfect-crystal cell the dislocation is inserted into; Box
+// is the row-major lattice matrix (each row is a lattice vector, same units
+// as Burgers).
+//
+// BuildPeriodicArray does not reorient this cell itself: Box[0], Box[1], and
+// Box[2] must already point along the dislocation frame's glide (m), normal
+// (n), and line (t) directions respectively (e.g. via an Atomsk -orient pass
+// on the input structure before building UnitCell, the same step
+// loading/uniaxial's orientStructure performs for its loading axis).
+// BuildPeriodicArray rejects a cell that isn't aligned this way.
+type UnitCell struct {
+	Box   [3]Vec3
+	Atoms []Atom
+}
+
+// BuildPeriodicArray replicates cell by size, evaluates the dislocation's
+// displacement field in the dislocation frame (m = glide direction, n =
+// slip-plane normal, t = line direction) at every atom and displaces the atom
+// by it (the cell itself is not rotated; see UnitCell), and writes the resulting LAMMPS data file plus an init.mod/relax.mod pair
+// shaped like the elastic-constants workflow's.
+func BuildPeriodicArray(cell UnitCell, slip SlipSystem, size BoxSize, opts Options) (Result, error) {
+	if opts.OutDir == "" {
+		opts.OutDir = "."
+	}
+
+	frame, err := computeDislocationFrame(slip)
+	if err != nil {
+		return Result{}, fmt.Errorf("periodicarray: %w", err)
+	}
+
+	if err := requireFrameAlignedCell(cell, frame); err != nil {
+		return Result{}, fmt.Errorf("periodicarray: %w", err)
+	}
+
+	// The compensating dislocation sits half a period away along the glide
+	// direction, so the pair's net Burgers content is zero across the full
+	// periodic cell; see dipoleField.
+	glideLength := dot(cell.Box[0], frame.M) * float64(maxInt(size.NB, 1))
+	field, err := newDisplacementField(slip, frame, opts, glideLength/2)
+	if err != nil {
+		return Result{}, fmt.Errorf("periodicarray: %w", err)
+	}
+
+	atoms, box := replicateAndDisplace(cell, size, frame, field)
+
+	numTypes := len(opts.Masses)
+	if numTypes == 0 {
+		return Result{}, fmt.Errorf("periodicarray: Options.Masses must declare at least one atom type's mass")
+	}
+
+	dataPath := opts.OutDir + "/dislocation.lmp"
+	if err := writeDataFile(dataPath, box, atoms, numTypes); err != nil {
+		return Result{}, fmt.Errorf("periodicarray: write data file: %w", err)
+	}
+
+	initPath := opts.OutDir + "/init.mod"
+	if err := os.WriteFile(initPath, []byte(renderInitMod("dislocation.lmp")), 0o644); err != nil {
+		return Result{}, fmt.Errorf("periodicarray: write init.mod: %w", err)
+	}
+
+	potPath := opts.OutDir + "/potential.mod"
+	if err := os.WriteFile(potPath, []byte(renderPotentialMod(opts.Masses)), 0o644); err != nil {
+		return Result{}, fmt.Errorf("periodicarray: write potential.mod: %w", err)
+	}
+
+	relaxPath := opts.OutDir + "/relax.mod"
+	if err := os.WriteFile(relaxPath, []byte(relaxModTemplate), 0o644); err != nil {
+		return Result{}, fmt.Errorf("periodicarray: write relax.mod: %w", err)
+	}
+
+	return Result{DataFile: dataPath, InitMod: initPath, PotentialMod: potPath, RelaxMod: relaxPath}, nil
+}
+
+// dislocationFrame is the orthonormal (m, n, t) basis the displacement
+// field is evaluated in: t along the line direction, n along the slip-plane
+// normal, m completing the right-handed set (the in-plane glide direction).
+type dislocationFrame struct {
+	M, N, T Vec3
+}
+
+func computeDislocationFrame(slip SlipSystem) (dislocationFrame, error) {
+	t := normalize(slip.Line)
+	n := normalize(slip.Plane)
+	if math.Abs(dot(t, n)) > 1e-6 {
+		return dislocationFrame{}, fmt.Errorf("line direction %v is not contained in the slip plane %v", slip.Line, slip.Plane)
+	}
+	m := normalize(cross(n, t))
+	return dislocationFrame{M: m, N: n, T: t}, nil
+}
+
+// fieldEvaluator evaluates a dislocation displacement field in the
+// dislocation frame, where x1 is measured along M and x2 along N. Satisfied
+// by a single displacementField and by dipoleField's sum of two.
+type fieldEvaluator interface {
+	displace(x1, x2 float64) Vec3
+}
+
+// displacementField evaluates the dislocation displacement u(x1, x2) in the
+// dislocation frame, where x1 is measured along M and x2 along N.
+type displacementField struct {
+	stroh    *strohSolution // nil => use isotropic fallback
+	edgeB    float64        // Burgers component along M (isotropic path)
+	screwB   float64        // Burgers component along T (isotropic path)
+	shearMod float64
+	poisson  float64
+}
+
+// dipoleField sums the displacement fields of two opposite-sign
+// dislocations separated by coreOffset along the glide direction (x1): the
+// compensating "image" dislocation the periodic-array-of-dislocations
+// method requires so the net Burgers content integrated around the full
+// periodic cell is zero, instead of a lone dislocation's field (which is
+// multivalued and incompatible with full periodicity on its own).
+type dipoleField struct {
+	pos, neg   *displacementField
+	coreOffset float64
+}
+
+func (d *dipoleField) displace(x1, x2 float64) Vec3 {
+	return add(d.pos.displace(x1, x2), d.neg.displace(x1-d.coreOffset, x2))
+}
+
+// newDisplacementField builds the compensating dislocation dipole: one
+// field for slip's Burgers vector at the core (x1=0), and a second,
+// opposite-sign field at x1=coreOffset.
+func newDisplacementField(slip SlipSystem, frame dislocationFrame, opts Options, coreOffset float64) (fieldEvaluator, error) {
+	pos, err := newSingleDisplacementField(slip, frame, opts)
+	if err != nil {
+		return nil, err
+	}
+	negSlip := slip
+	negSlip.Burgers = scale(slip.Burgers, -1)
+	neg, err := newSingleDisplacementField(negSlip, frame, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &dipoleField{pos: pos, neg: neg, coreOffset: coreOffset}, nil
+}
+
+func newSingleDisplacementField(slip SlipSystem, frame dislocationFrame, opts Options) (*displacementField, error) {
+	if opts.ElasticConstants != nil {
+		sol, err := solveStroh(*opts.ElasticConstants, frame, slip.Burgers)
+		if err != nil {
+			return nil, fmt.Errorf("stroh formalism failed, and no isotropic fallback requested: %w", err)
+		}
+		return &displacementField{stroh: sol}, nil
+	}
+	return &displacementField{
+		edgeB:    dot(slip.Burgers, frame.M),
+		screwB:   dot(slip.Burgers, frame.T),
+		shearMod: opts.ShearModulus,
+		poisson:  opts.PoissonRatio,
+	}, nil
+}
+
+// displace returns the displacement, in the dislocation frame, at an atom
+// located at (x1, x2) relative to the dislocation core.
+func (f *displacementField) displace(x1, x2 float64) Vec3 {
+	if f.stroh != nil {
+		return f.stroh.displace(x1, x2)
+	}
+	return volterraDisplacement(x1, x2, f.edgeB, f.screwB, f.shearMod, f.poisson)
+}
+
+// volterraDisplacement is the classical isotropic-elasticity displacement
+// field of a straight mixed dislocation at the origin, decomposed into an
+// edge component (Burgers vector in-plane, along x1) and a screw component
+// (Burgers vector along the line, x3/out-of-plane here).
+func volterraDisplacement(x1, x2, edgeB, screwB, mu, nu float64) Vec3 {
+	r2 := x1*x1 + x2*x2
+	if r2 == 0 {
+		return Vec3{}
+	}
+	_ = mu // retained for API symmetry with anisotropic path; isotropic field is mu-independent
+	u1 := edgeB / (2 * math.Pi) * (math.Atan2(x2, x1) + (x1*x2)/(2*(1-nu)*r2))
+	u2 := -edgeB / (2 * math.Pi) * ((1-2*nu)/(4*(1-nu))*math.Log(r2) + (x1*x1-x2*x2)/(4*(1-nu)*r2))
+	u3 := screwB / (2 * math.Pi) * math.Atan2(x2, x1)
+	return Vec3{u1, u2, u3}
+}
+
+// strohSolution holds the per-branch eigenvalues p_alpha, displacement
+// eigenvectors A, and the coefficients D solving the Burgers-vector boundary
+// condition, so displace() can evaluate u_k = Re[sum_a A[k][a]*D[a]*ln(eta_a)]/(2 pi i).
+type strohSolution struct {
+	p [3]complex128
+	a [3][3]complex128 // a[alpha][k]
+	d [3]complex128
+}
+
+func (s *strohSolution) displace(x1, x2 float64) Vec3 {
+	var u Vec3
+	for k := 0; k < 3; k++ {
+		var sum complex128
+		for alpha := 0; alpha < 3; alpha++ {
+			eta := complex(x1, 0) + s.p[alpha]*complex(x2, 0)
+			sum += s.a[alpha][k] * s.d[alpha] * cmplx.Log(eta)
+		}
+		u[k] = real(sum / complex(0, 2*math.Pi))
+	}
+	return u
+}
+
+// solveStroh builds the Stroh sextic for the stiffness tensor c (already in
+// the crystal Cartesian frame) rotated into frame, finds its three roots
+// with positive imaginary part, their displacement eigenvectors, and the
+// coefficients enforcing a net Burgers vector b around the core.
+func solveStroh(c [6][6]float64, frame dislocationFrame, b Vec3) (*strohSolution, error) {
+	cFull := voigtToFull(c)
+	cRot := rotateStiffness(cFull, frame.M, frame.N, frame.T)
+
+	q := christoffel(cRot, 0, 0) // m-m
+	r := christoffel(cRot, 0, 1) // m-n
+	t := christoffel(cRot, 1, 1) // n-n
+
+	coeffs := sexticCoefficients(q, r, t)
+	roots, err := findRoots(coeffs)
+	if err != nil {
+		return nil, fmt.Errorf("sextic root finding: %w", err)
+	}
+
+	var sol strohSolution
+	idx := 0
+	for _, p := range roots {
+		if imag(p) > 1e-9 {
+			if idx >= 3 {
+				continue
+			}
+			sol.p[idx] = p
+			idx++
+		}
+	}
+	if idx != 3 {
+		return nil, fmt.Errorf("expected 3 roots with positive imaginary part, found %d", idx)
+	}
+
+	var a [3][3]complex128
+	for alpha, p := range sol.p {
+		m := matAdd3(q, matAdd3(matScale3(matAddT3(r), p), matScale3(t, p*p)))
+		vec, err := nullVector(m)
+		if err != nil {
+			return nil, fmt.Errorf("branch %d: %w", alpha, err)
+		}
+		a[alpha] = vec
+	}
+	sol.a = a
+
+	// A is the 3x3 matrix whose columns are a[alpha]; solve A*D = b for D.
+	var aMat [3][3]complex128
+	for alpha := 0; alpha < 3; alpha++ {
+		for k := 0; k < 3; k++ {
+			aMat[k][alpha] = a[alpha][k]
+		}
+	}
+	bVec := [3]complex128{complex(b[0], 0), complex(b[1], 0), complex(b[2], 0)}
+	d, err := solveLinear3(aMat, bVec)
+	if err != nil {
+		return nil, fmt.Errorf("solving for Burgers coefficients: %w", err)
+	}
+	sol.d = d
+	return &sol, nil
+}
+
+// christoffel contracts the full stiffness tensor with two of the three
+// dislocation-frame basis directions (0=m, 1=n) to form one of the 3x3
+// blocks (Q, R, T) used by the Stroh sextic.
+func christoffel(c [3][3][3][3]float64, dirI, dirJ int) [3][3]complex128 {
+	var out [3][3]complex128
+	for i := 0; i < 3; i++ {
+		for k := 0; k < 3; k++ {
+			var sum float64
+			for j := 0; j < 3; j++ {
+				for l := 0; l < 3; l++ {
+					sum += c[i][j][k][l] * basisComponent(dirI, j) * basisComponent(dirJ, l)
+				}
+			}
+			out[i][k] = complex(sum, 0)
+		}
+	}
+	return out
+}
+
+// basisComponent returns 1 if axis == which (0=m, 1=n, 2=t), else 0: the
+// stiffness has already been rotated into the (m, n, t) frame by
+// rotateStiffness, so contraction directions are just the unit basis
+// vectors of that frame.
+func basisComponent(which, axis int) float64 {
+	if which == axis {
+		return 1
+	}
+	return 0
+}
+
+func sexticCoefficients(q, r, t [3][3]complex128) [7]complex128 {
+	sample := func(p complex128) complex128 {
+		m := matAdd3(q, matAdd3(matScale3(matAddT3(r), p), matScale3(t, p*p)))
+		return det3(m)
+	}
+	// Interpolate the degree-6 polynomial from 7 samples via a Vandermonde
+	// solve; avoids hand-expanding the symbolic determinant.
+	pts := [7]complex128{0, 1, -1, 2, -2, 3, -3}
+	vals := [7]complex128{}
+	for i, p := range pts {
+		vals[i] = sample(p)
+	}
+	return vandermondeSolve7(pts, vals)
+}
+
+// findRoots extracts the roots of the degree-6 polynomial with coefficients
+// coeffs[0] + coeffs[1]*p + ... + coeffs[6]*p^6 via Durand-Kerner iteration,
+// run until the largest per-iteration root correction falls below a
+// tolerance (at most maxIter iterations), after which every root's residual
+// is checked, so a poorly converged result is reported instead of silently
+// accepted.
+func findRoots(coeffs [7]complex128) ([6]complex128, error) {
+	n := 6
+	for n > 0 && coeffs[n] == 0 {
+		n--
+	}
+	roots := make([]complex128, 6)
+	for i := range roots {
+		roots[i] = cmplx.Rect(0.9, 2*math.Pi*float64(i)/6+0.3)
+	}
+	eval := func(p complex128) complex128 {
+		var v complex128
+		for k := n; k >= 0; k-- {
+			v = v*p + coeffs[k]
+		}
+		return v
+	}
+	lead := coeffs[n]
+	const maxIter = 500
+	const tol = 1e-10
+	converged := false
+	for iter := 0; iter < maxIter; iter++ {
+		maxShift := 0.0
+		for i := range roots {
+			num := eval(roots[i]) / lead
+			den := complex128(1)
+			for j := range roots {
+				if j != i {
+					den *= roots[i] - roots[j]
+				}
+			}
+			if den == 0 {
+				continue
+			}
+			shift := num / den
+			roots[i] -= shift
+			if s := cmplx.Abs(shift); s > maxShift {
+				maxShift = s
+			}
+		}
+		if maxShift < tol {
+			converged = true
+			break
+		}
+	}
+	var out [6]complex128
+	copy(out[:], roots)
+	if !converged {
+		return out, fmt.Errorf("Durand-Kerner root finding did not converge (root corrections still above %g) within %d iterations", tol, maxIter)
+	}
+	for _, r := range roots {
+		if cmplx.Abs(eval(r)/lead) > 1e-6 {
+			return out, fmt.Errorf("root %v has residual %g above tolerance", r, cmplx.Abs(eval(r)/lead))
+		}
+	}
+	return out, nil
+}
+
+// requireFrameAlignedCell rejects a UnitCell whose lattice vectors are not
+// parallel to the dislocation frame's (m, n, t) directions, in that order.
+// replicateAndDisplace replicates along cell.Box[0]/[1]/[2] rather than
+// frame.M/N/T directly (so NB/NN/NXi only grow the box along (b, n, xi) when
+// those coincide), and writeDataFile's xy/xz/yz tilt convention requires
+// Box[2] to be the cell's c-vector regardless — both assume the caller
+// already rotated the input structure onto the slip system, per UnitCell's
+// doc comment.
+func requireFrameAlignedCell(cell UnitCell, frame dislocationFrame) error {
+	aligned := func(axis, want Vec3) bool {
+		return math.Abs(math.Abs(dot(normalize(axis), want))-1) < 1e-3
+	}
+	if !aligned(cell.Box[0], frame.M) {
+		return fmt.Errorf("cell.Box[0] (%v) is not parallel to the dislocation frame's glide direction m (%v); reorient the input structure onto the slip system before calling BuildPeriodicArray", cell.Box[0], frame.M)
+	}
+	if !aligned(cell.Box[1], frame.N) {
+		return fmt.Errorf("cell.Box[1] (%v) is not parallel to the dislocation frame's normal direction n (%v); reorient the input structure onto the slip system before calling BuildPeriodicArray", cell.Box[1], frame.N)
+	}
+	if !aligned(cell.Box[2], frame.T) {
+		return fmt.Errorf("cell.Box[2] (%v) is not parallel to the dislocation frame's line direction t (%v); reorient the input structure onto the slip system before calling BuildPeriodicArray", cell.Box[2], frame.T)
+	}
+	return nil
+}
+
+// replicateAndDisplace replicates cell NB/NN/NXi times along its own
+// Box[0]/[1]/[2] lattice vectors (validated by requireFrameAlignedCell to
+// already run along the dislocation frame's m/n/t directions) and displaces
+// every resulting atom by field, evaluated at each atom's (x1, x2)
+// coordinates in that frame.
+func replicateAndDisplace(cell UnitCell, size BoxSize, frame dislocationFrame, field fieldEvaluator) ([]Atom, [3]Vec3) {
+	var atoms []Atom
+	nb, nn, nxi := maxInt(size.NB, 1), maxInt(size.NN, 1), maxInt(size.NXi, 1)
+	for ib := 0; ib < nb; ib++ {
+		for in := 0; in < nn; in++ {
+			for ix := 0; ix < nxi; ix++ {
+				for _, atom := range cell.Atoms {
+					cart := fracToCart(cell.Box, atom.Frac)
+					replicaShift := add(scale(cell.Box[0], float64(ib)), add(scale(cell.Box[1], float64(in)), scale(cell.Box[2], float64(ix))))
+					cart = add(cart, replicaShift)
+					x1, x2 := dot(cart, frame.M), dot(cart, frame.N)
+					u := field.displace(x1, x2)
+					disp := add(scale(frame.M, u[0]), add(scale(frame.N, u[1]), scale(frame.T, u[2])))
+					atoms = append(atoms, Atom{Type: atom.Type, Frac: add(cart, disp)})
+				}
+			}
+		}
+	}
+	box := [3]Vec3{
+		scale(cell.Box[0], float64(nb)),
+		scale(cell.Box[1], float64(nn)),
+		scale(cell.Box[2], float64(nxi)),
+	}
+	return atoms, box
+}
+
+func writeDataFile(path string, box [3]Vec3, atoms []Atom, numTypes int) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	fmt.Fprintf(f, "# dislocation.lmp generated by dislocation/periodic_array\n\n")
+	fmt.Fprintf(f, "%d atoms\n", len(atoms))
+	fmt.Fprintf(f, "%d atom types\n\n", numTypes)
+	fmt.Fprintf(f, "0.0 %g xlo xhi\n", box[0][0])
+	fmt.Fprintf(f, "0.0 %g ylo yhi\n", box[1][1])
+	fmt.Fprintf(f, "0.0 %g zlo zhi\n", box[2][2])
+	fmt.Fprintf(f, "%g %g %g xy xz yz\n\n", box[1][0], box[2][0], box[2][1])
+	fmt.Fprintf(f, "Atoms\n\n")
+	for i, a := range atoms {
+		fmt.Fprintf(f, "%d %d %g %g %g\n", i+1, a.Type, a.Frac[0], a.Frac[1], a.Frac[2])
+	}
+	return nil
+}
+
+func renderInitMod(dataFile string) string {
+	return fmt.Sprintf(`
+# ========================================================================
+# init.mod (generated by dislocation/periodic_array)
+# Same minimization block, jiggle, and unit handling as the elastic-constants
+# workflow's init.mod, so the two subsystems share a post-processing path.
+# ========================================================================
+
+variable up equal 1.0e-6
+variable atomjiggle equal 1.0e-5
+
+units           metal
+dimension       3
+boundary        p p p
+atom_style      atomic
+
+variable cfac equal 1.0e-4
+variable cunits string GPa
+
+variable etol equal 0.0
+variable ftol equal 1.0e-10
+variable maxiter equal 100
+variable maxeval equal 1000
+variable dmax equal 1.0e-2
+
+read_data %s
+`, dataFile)
+}
+
+// renderPotentialMod emits the mass declarations LAMMPS requires for
+// atom_style atomic plus a placeholder pair_style, mirroring
+// elastic/initgen's potential.mod (pair_style/pair_coeff is left for the
+// caller to fill in or overwrite with the potential used to generate
+// ElasticConstants).
+func renderPotentialMod(masses []float64) string {
+	s := "# potential.mod – interatomic potential definition\n"
+	s += "# NOTE: Replace pair_style/pair_coeff below with the potential for this run.\n\n"
+	for i, m := range masses {
+		s += fmt.Sprintf("mass %d %g\n", i+1, m)
+	}
+	s += "\npair_style      none\n# pair_coeff    * *\n\n"
+	s += "neighbor 1.0 bin\nneigh_modify once no every 1 delay 0 check yes\n"
+	return s
+}
+
+// relaxModTemplate minimizes the inserted dislocation with conjugate
+// gradient, then box-relaxes only the line-direction cell vector (the
+// data file's c-vector/z here, since BuildPeriodicArray requires cell.Box[2]
+// to run along the dislocation line direction xi via
+// requireFrameAlignedCell) so the core can relax its line-direction
+// periodicity without the glide/normal directions drifting.
+const relaxModTemplate = `# relax.mod – relax a periodic-array dislocation cell
+min_style       cg
+minimize        ${etol} ${ftol} ${maxiter} ${maxeval}
+
+fix             boxrelax all box/relax z 0.0 vmax 0.001
+minimize        ${etol} ${ftol} ${maxiter} ${maxeval}
+unfix           boxrelax
+`
+
+// --- small linear-algebra helpers (3x3 real/complex, no external deps) ---
+
+func normalize(v Vec3) Vec3 {
+	n := math.Sqrt(v[0]*v[0] + v[1]*v[1] + v[2]*v[2])
+	return Vec3{v[0] / n, v[1] / n, v[2] / n}
+}
+
+func dot(a, b Vec3) float64 { return a[0]*b[0] + a[1]*b[1] + a[2]*b[2] }
+
+func cross(a, b Vec3) Vec3 {
+	return Vec3{
+		a[1]*b[2] - a[2]*b[1],
+		a[2]*b[0] - a[0]*b[2],
+		a[0]*b[1] - a[1]*b[0],
+	}
+}
+
+func add(a, b Vec3) Vec3           { return Vec3{a[0] + b[0], a[1] + b[1], a[2] + b[2]} }
+func scale(a Vec3, s float64) Vec3 { return Vec3{a[0] * s, a[1] * s, a[2] * s} }
+
+func fracToCart(box [3]Vec3, frac Vec3) Vec3 {
+	return add(scale(box[0], frac[0]), add(scale(box[1], frac[1]), scale(box[2], frac[2])))
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// voigtToFull expands a 6x6 Voigt stiffness matrix into the full symmetric
+// 4th-rank elastic tensor C_ijkl.
+func voigtToFull(c [6][6]float64) [3][3][3][3]float64 {
+	voigt := func(i, j int) int {
+		if i == j {
+			return i
+		}
+		return 6 - i - j
+	}
+	var out [3][3][3][3]float64
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			for k := 0; k < 3; k++ {
+				for l := 0; l < 3; l++ {
+					out[i][j][k][l] = c[voigt(i, j)][voigt(k, l)]
+				}
+			}
+		}
+	}
+	return out
+}
+
+// rotateStiffness rotates the full stiffness tensor from the crystal
+// Cartesian frame into the (m, n, t) dislocation frame.
+func rotateStiffness(c [3][3][3][3]float64, m, n, t Vec3) [3][3][3][3]float64 {
+	r := [3]Vec3{m, n, t}
+	var out [3][3][3][3]float64
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			for k := 0; k < 3; k++ {
+				for l := 0; l < 3; l++ {
+					var sum float64
+					for p := 0; p < 3; p++ {
+						for q := 0; q < 3; q++ {
+							for s := 0; s < 3; s++ {
+								for u := 0; u < 3; u++ {
+									sum += r[i][p] * r[j][q] * r[k][s] * r[l][u] * c[p][q][s][u]
+								}
+							}
+						}
+					}
+					out[i][j][k][l] = sum
+				}
+			}
+		}
+	}
+	return out
+}
+
+func matAdd3(a, b [3][3]complex128) [3][3]complex128 {
+	var out [3][3]complex128
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			out[i][j] = a[i][j] + b[i][j]
+		}
+	}
+	return out
+}
+
+func matScale3(a [3][3]complex128, s complex128) [3][3]complex128 {
+	var out [3][3]complex128
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			out[i][j] = a[i][j] * s
+		}
+	}
+	return out
+}
+
+func matAddT3(a [3][3]complex128) [3][3]complex128 {
+	var out [3][3]complex128
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			out[i][j] = a[i][j] + a[j][i]
+		}
+	}
+	return out
+}
+
+func det3(m [3][3]complex128) complex128 {
+	return m[0][0]*(m[1][1]*m[2][2]-m[1][2]*m[2][1]) -
+		m[0][1]*(m[1][0]*m[2][2]-m[1][2]*m[2][0]) +
+		m[0][2]*(m[1][0]*m[2][1]-m[1][1]*m[2][0])
+}
+
+// nullVector returns a unit vector in the null space of the (generically
+// rank-2) 3x3 matrix m, via the cross product of two of its rows.
+func nullVector(m [3][3]complex128) ([3]complex128, error) {
+	row := func(i int) [3]complex128 { return m[i] }
+	crossC := func(a, b [3]complex128) [3]complex128 {
+		return [3]complex128{
+			a[1]*b[2] - a[2]*b[1],
+			a[2]*b[0] - a[0]*b[2],
+			a[0]*b[1] - a[1]*b[0],
+		}
+	}
+	best := [3]complex128{}
+	bestNorm := 0.0
+	pairs := [][2]int{{0, 1}, {0, 2}, {1, 2}}
+	for _, p := range pairs {
+		v := crossC(row(p[0]), row(p[1]))
+		n := cmplx.Abs(v[0])*cmplx.Abs(v[0]) + cmplx.Abs(v[1])*cmplx.Abs(v[1]) + cmplx.Abs(v[2])*cmplx.Abs(v[2])
+		if n > bestNorm {
+			bestNorm = n
+			best = v
+		}
+	}
+	if bestNorm < 1e-18 {
+		return best, fmt.Errorf("matrix is (near-)zero; cannot find a null vector")
+	}
+	norm := cmplx.Sqrt(complex(bestNorm, 0))
+	return [3]complex128{best[0] / norm, best[1] / norm, best[2] / norm}, nil
+}
+
+func solveLinear3(m [3][3]complex128, b [3]complex128) ([3]complex128, error) {
+	d := det3(m)
+	if cmplx.Abs(d) < 1e-18 {
+		return [3]complex128{}, fmt.Errorf("singular 3x3 system")
+	}
+	var x [3]complex128
+	for col := 0; col < 3; col++ {
+		mc := m
+		for row := 0; row < 3; row++ {
+			mc[row][col] = b[row]
+		}
+		x[col] = det3(mc) / d
+	}
+	return x, nil
+}
+
+// vandermondeSolve7 solves for the 7 coefficients of a degree-6 polynomial
+// given its values at 7 known points, by direct Gaussian elimination on the
+// Vandermonde system.
+func vandermondeSolve7(pts, vals [7]complex128) [7]complex128 {
+	var a [7][8]complex128
+	for i := 0; i < 7; i++ {
+		p := complex128(1)
+		for j := 0; j < 7; j++ {
+			a[i][j] = p
+			p *= pts[i]
+		}
+		a[i][7] = vals[i]
+	}
+	for col := 0; col < 7; col++ {
+		pivotRow := col
+		for row := col; row < 7; row++ {
+			if cmplx.Abs(a[row][col]) > cmplx.Abs(a[pivotRow][col]) {
+				pivotRow = row
+			}
+		}
+		a[col], a[pivotRow] = a[pivotRow], a[col]
+		pivot := a[col][col]
+		for j := col; j < 8; j++ {
+			a[col][j] /= pivot
+		}
+		for row := 0; row < 7; row++ {
+			if row == col {
+				continue
+			}
+			factor := a[row][col]
+			for j := col; j < 8; j++ {
+				a[row][j] -= factor * a[col][j]
+			}
+		}
+	}
+	var coeffs [7]complex128
+	for i := 0; i < 7; i++ {
+		coeffs[i] = a[i][7]
+	}
+	return coeffs
+}
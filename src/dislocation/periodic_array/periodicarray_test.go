@@ -0,0 +1,110 @@
+package periodicarray
+
+import (
+	"math"
+	"testing"
+)
+
+// cubicCij builds a Voigt stiffness tensor for a cubic crystal from its
+// three independent constants, e.g. FCC Al-like values (107, 61, 28 GPa):
+// genuinely anisotropic (unlike the isotropic limit c44 = (c11-c12)/2), so
+// the Stroh sextic has three simple roots rather than the degenerate
+// repeated root isotropic elasticity produces.
+func cubicCij(c11, c12, c44 float64) [6][6]float64 {
+	var c [6][6]float64
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			if i == j {
+				c[i][j] = c11
+			} else {
+				c[i][j] = c12
+			}
+		}
+	}
+	for i := 3; i < 6; i++ {
+		c[i][i] = c44
+	}
+	return c
+}
+
+func TestFindRootsConverges(t *testing.T) {
+	// (p-1)(p-2)(p-3)(p+1)(p+2)(p+3) = 0, expanded: roots are {1,2,3,-1,-2,-3}.
+	coeffs := [7]complex128{-36, 0, 49, 0, -14, 0, 1}
+	roots, err := findRoots(coeffs)
+	if err != nil {
+		t.Fatalf("findRoots() error = %v", err)
+	}
+	want := map[float64]bool{1: false, 2: false, 3: false, -1: false, -2: false, -3: false}
+	for _, r := range roots {
+		if math.Abs(imag(r)) > 1e-6 {
+			t.Errorf("root %v has unexpected non-zero imaginary part", r)
+			continue
+		}
+		found := false
+		for w := range want {
+			if math.Abs(real(r)-w) < 1e-6 {
+				want[w] = true
+				found = true
+				break
+			}
+		}
+		if !found {
+			t.Errorf("root %v does not match any expected root", r)
+		}
+	}
+	for w, ok := range want {
+		if !ok {
+			t.Errorf("expected root %g not found among %v", w, roots)
+		}
+	}
+}
+
+func TestSolveStrohLinearInBurgersVector(t *testing.T) {
+	c := cubicCij(107, 61, 28)
+	frame := dislocationFrame{M: Vec3{1, 0, 0}, N: Vec3{0, 1, 0}, T: Vec3{0, 0, 1}}
+	b := Vec3{1, 0, 0} // pure edge: Burgers vector along M
+
+	sol, err := solveStroh(c, frame, b)
+	if err != nil {
+		t.Fatalf("solveStroh() error = %v", err)
+	}
+	solDouble, err := solveStroh(c, frame, Vec3{2, 0, 0})
+	if err != nil {
+		t.Fatalf("solveStroh() with doubled Burgers vector error = %v", err)
+	}
+
+	for _, pt := range [][2]float64{{1, 0.5}, {-2, 1}, {3, -1.5}} {
+		x1, x2 := pt[0], pt[1]
+		got := sol.displace(x1, x2)
+		gotDouble := solDouble.displace(x1, x2)
+		for k := 0; k < 3; k++ {
+			if math.IsNaN(got[k]) || math.IsInf(got[k], 0) {
+				t.Fatalf("displace(%g, %g)[%d] = %g, want a finite value", x1, x2, k, got[k])
+			}
+			want := 2 * got[k]
+			if math.Abs(gotDouble[k]-want) > 1e-6*math.Max(1, math.Abs(want)) {
+				t.Errorf("doubling the Burgers vector gave displace(%g, %g)[%d] = %g, want %g (the boundary condition is linear in b)", x1, x2, k, gotDouble[k], want)
+			}
+		}
+	}
+}
+
+func TestDipoleFieldCancelsFarFromEitherCore(t *testing.T) {
+	mu, nu := 1.0, 0.3
+	frame := dislocationFrame{M: Vec3{1, 0, 0}, N: Vec3{0, 1, 0}, T: Vec3{0, 0, 1}}
+	slip := SlipSystem{Burgers: Vec3{1, 0, 0}, Plane: Vec3{0, 1, 0}, Line: Vec3{0, 0, 1}}
+	field, err := newDisplacementField(slip, frame, Options{ShearModulus: mu, PoissonRatio: nu}, 100)
+	if err != nil {
+		t.Fatalf("newDisplacementField() error = %v", err)
+	}
+
+	// Far outside the dipole span, the two opposite-sign fields' long-range
+	// (log-divergent) parts should very nearly cancel rather than grow.
+	near := field.displace(1, 0.5)
+	far := field.displace(1e6, 0.5)
+	for k := 0; k < 3; k++ {
+		if math.Abs(far[k]) > math.Abs(near[k]) {
+			t.Errorf("dipole displacement[%d] grew further from the cores (%g at x1=1 vs %g at x1=1e6); want it to stay bounded", k, near[k], far[k])
+		}
+	}
+}
@@ -0,0 +1,296 @@
+// Package initgen builds the init.mod / potential.mod / displace.mod triple
+// used by the elastic-constants workflow directly from a raw structure file
+// (CIF, POSCAR, xyz, Atomsk-native, ...), instead of requiring the user to
+// hand-produce a triclinic LAMMPS data file first.
+package initgen
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Symmetry is the crystal system detected (or asserted) for the input
+// structure. It drives the default deformation/minimization parameters,
+// since a cubic cell tolerates larger jiggle and a looser dmax than a
+// low-symmetry one.
+type Symmetry string
+
+const (
+	SymmetryCubic      Symmetry = "cubic"
+	SymmetryHexagonal  Symmetry = "hexagonal"
+	SymmetryTetragonal Symmetry = "tetragonal"
+	SymmetryTriclinic  Symmetry = "triclinic"
+)
+
+// Converter selects the external tool used to turn the input structure into
+// a triclinic LAMMPS data file.
+type Converter string
+
+const (
+	ConverterAtomsk Converter = "atomsk"
+	ConverterASE    Converter = "ase"
+)
+
+// Options controls GenerateInitMod. Zero-value Options is valid: the
+// symmetry is auto-detected from the input file and Atomsk is used as the
+// converter.
+type Options struct {
+	// Converter picks the external structure-conversion tool. Defaults to
+	// ConverterAtomsk.
+	Converter Converter
+	// Symmetry overrides auto-detection when the caller already knows the
+	// crystal system.
+	Symmetry Symmetry
+	// OutDir is the directory the generated files are written to. Defaults
+	// to the directory containing structPath.
+	OutDir string
+	// DataFile is the name of the LAMMPS data file produced by the
+	// converter and referenced by the generated read_data line. Defaults
+	// to "structure_triclinic.lmp".
+	DataFile string
+	// Units selects the LAMMPS unit system. Defaults to UnitMetal.
+	Units UnitSystem
+	// OutputUnit is the pressure unit the elastic-constants post-processing
+	// should report in (e.g. "GPa", "bar"). Defaults to "GPa".
+	OutputUnit string
+}
+
+// deformDefaults holds the per-symmetry defaults for the parameters that
+// init.mod exposes as LAMMPS variables.
+type deformDefaults struct {
+	up         float64
+	atomjiggle float64
+	etol       float64
+	ftol       float64
+	dmax       float64
+	maxiter    int
+	maxeval    int
+}
+
+// defaultsBySymmetry mirrors the tolerances LAMMPS' own examples/elastic
+// script uses for cubic metals, tightened for lower-symmetry cells where
+// the deformation couples more directions and needs smaller steps to stay
+// in the harmonic regime.
+var defaultsBySymmetry = map[Symmetry]deformDefaults{
+	SymmetryCubic:      {up: 1.0e-6, atomjiggle: 1.0e-5, etol: 0.0, ftol: 1.0e-10, dmax: 1.0e-2, maxiter: 100, maxeval: 1000},
+	SymmetryHexagonal:  {up: 1.0e-6, atomjiggle: 1.0e-5, etol: 0.0, ftol: 1.0e-10, dmax: 5.0e-3, maxiter: 200, maxeval: 2000},
+	SymmetryTetragonal: {up: 5.0e-7, atomjiggle: 1.0e-5, etol: 0.0, ftol: 1.0e-10, dmax: 5.0e-3, maxiter: 200, maxeval: 2000},
+	SymmetryTriclinic:  {up: 5.0e-7, atomjiggle: 5.0e-6, etol: 0.0, ftol: 1.0e-11, dmax: 1.0e-3, maxiter: 300, maxeval: 3000},
+}
+
+// Result reports what GenerateInitMod produced.
+type Result struct {
+	InitMod      string
+	PotentialMod string
+	DisplaceMod  string
+	DataFile     string
+	Symmetry     Symmetry
+}
+
+// GenerateInitMod converts structPath to a triclinic LAMMPS data file and
+// writes the init.mod, potential.mod and displace.mod triple needed to run
+// the elastic-constants workflow against it.
+func GenerateInitMod(structPath string, opts Options) (Result, error) {
+	if opts.Converter == "" {
+		opts.Converter = ConverterAtomsk
+	}
+	if opts.DataFile == "" {
+		opts.DataFile = "structure_triclinic.lmp"
+	}
+	if opts.OutDir == "" {
+		opts.OutDir = filepath.Dir(structPath)
+	}
+	if opts.Units == "" {
+		opts.Units = UnitMetal
+	}
+	if opts.OutputUnit == "" {
+		opts.OutputUnit = "GPa"
+	}
+	unitBlock, err := ResolveUnitBlock(opts.Units, opts.OutputUnit)
+	if err != nil {
+		return Result{}, fmt.Errorf("initgen: %w", err)
+	}
+
+	sym := opts.Symmetry
+	if sym == "" {
+		detected, err := detectSymmetry(structPath, opts.Converter)
+		if err != nil {
+			return Result{}, fmt.Errorf("initgen: detect symmetry: %w", err)
+		}
+		sym = detected
+	}
+
+	dataFile := filepath.Join(opts.OutDir, opts.DataFile)
+	if err := convertToTriclinic(structPath, dataFile, opts.Converter); err != nil {
+		return Result{}, fmt.Errorf("initgen: convert structure: %w", err)
+	}
+
+	d := defaultsBySymmetry[sym]
+	if d == (deformDefaults{}) {
+		d = defaultsBySymmetry[SymmetryCubic]
+	}
+
+	initPath := filepath.Join(opts.OutDir, "init.mod")
+	if err := os.WriteFile(initPath, []byte(renderInitMod(opts.DataFile, d, unitBlock)), 0o644); err != nil {
+		return Result{}, fmt.Errorf("initgen: write init.mod: %w", err)
+	}
+
+	potPath := filepath.Join(opts.OutDir, "potential.mod")
+	if err := os.WriteFile(potPath, []byte(potentialModTemplate), 0o644); err != nil {
+		return Result{}, fmt.Errorf("initgen: write potential.mod: %w", err)
+	}
+
+	dispPath := filepath.Join(opts.OutDir, "displace.mod")
+	if err := os.WriteFile(dispPath, []byte(displaceModTemplate), 0o644); err != nil {
+		return Result{}, fmt.Errorf("initgen: write displace.mod: %w", err)
+	}
+
+	return Result{
+		InitMod:      initPath,
+		PotentialMod: potPath,
+		DisplaceMod:  dispPath,
+		DataFile:     dataFile,
+		Symmetry:     sym,
+	}, nil
+}
+
+// convertToTriclinic shells out to the selected external tool to produce a
+// LAMMPS data file with explicit xy/xz/yz tilt factors, even when the input
+// cell is orthogonal (Atomsk always emits the three tilt keywords).
+func convertToTriclinic(structPath, dataFile string, conv Converter) error {
+	var cmd *exec.Cmd
+	switch conv {
+	case ConverterAtomsk:
+		cmd = exec.Command("atomsk", structPath, "lmp", "-prefix", dataFile)
+	case ConverterASE:
+		cmd = exec.Command("ase", "convert", structPath, dataFile)
+	default:
+		return fmt.Errorf("unknown converter %q", conv)
+	}
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%s: %w: %s", cmd.Path, err, out)
+	}
+	return nil
+}
+
+// detectSymmetry, for ConverterAtomsk, shells out to
+// `atomsk <structPath> -properties symmetry` and maps its report onto the
+// Symmetry enum. Unknown or unrecognized reports fall back to
+// SymmetryTriclinic, the safest (most conservative) default. Other
+// converters run no symmetry analysis and always get SymmetryTriclinic.
+func detectSymmetry(structPath string, conv Converter) (Symmetry, error) {
+	if conv != ConverterAtomsk {
+		return SymmetryTriclinic, nil
+	}
+	out, err := exec.Command("atomsk", structPath, "-properties", "symmetry").CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", err, out)
+	}
+	return parseSymmetryReport(string(out)), nil
+}
+
+func parseSymmetryReport(report string) Symmetry {
+	lower := strings.ToLower(report)
+	switch {
+	case strings.Contains(lower, "cubic"):
+		return SymmetryCubic
+	case strings.Contains(lower, "hexagonal"):
+		return SymmetryHexagonal
+	case strings.Contains(lower, "tetragonal"):
+		return SymmetryTetragonal
+	default:
+		return SymmetryTriclinic
+	}
+}
+
+func renderInitMod(dataFile string, d deformDefaults, u UnitBlock) string {
+	return fmt.Sprintf(`
+# ========================================================================
+# init.mod (generated by elastic/initgen)
+# Define units, deformation parameters, and read a prepared structure file
+# ========================================================================
+
+# --- Deformation parameters ---
+variable up equal %g             # Finite deformation size
+variable atomjiggle equal %g     # Small random jiggle to break symmetry
+
+# --- Units & elastic constants scaling ---
+%s
+dimension       3
+boundary        p p p
+atom_style      atomic
+
+variable cfac equal %g           # Conversion factor to %s
+variable cunits string %s
+
+# --- Minimization settings ---
+variable etol equal %g
+variable ftol equal %g
+variable maxiter equal %d
+variable maxeval equal %d
+variable dmax equal %g
+
+# --- Load structure ---
+# Generated from the input structure via Atomsk/ASE; box is triclinic with
+# explicit xy/xz/yz tilt factors so xy/xz/yz deformation below is valid.
+
+read_data %s
+`, d.up, d.atomjiggle, u.UnitsLine, u.Cfac, u.Cunits, u.Cunits, d.etol, d.ftol, d.maxiter, d.maxeval, d.dmax, dataFile)
+}
+
+// potentialModTemplate is the standard potential.mod companion file: it is
+// potential-specific in real use (pair_style/pair_coeff), left as a
+// placeholder for the caller to fill in or overwrite.
+const potentialModTemplate = `# potential.mod – interatomic potential definition
+# NOTE: Replace pair_style/pair_coeff below with the potential for this run.
+
+pair_style      none
+# pair_coeff    * *
+
+neighbor 1.0 bin
+neigh_modify once no every 1 delay 0 check yes
+`
+
+// displaceModTemplate is the finite-deformation driver shared by every
+// elastic-constants run; it is independent of structure and potential, so
+// it is emitted verbatim alongside init.mod.
+const displaceModTemplate = `# displace.mod – apply a finite deformation and measure the stress response
+# Usage: included once per strain direction/sign by the driving script.
+
+if "${dir} == 1" then &
+   "variable len0 equal ${lx0}"
+if "${dir} == 2" then &
+   "variable len0 equal ${ly0}"
+if "${dir} == 3" then &
+   "variable len0 equal ${lz0}"
+if "${dir} == 4" then &
+   "variable len0 equal ${lz0}"
+if "${dir} == 5" then &
+   "variable len0 equal ${lz0}"
+if "${dir} == 6" then &
+   "variable len0 equal ${ly0}"
+
+variable delta equal -${up}*${len0}
+variable deltaxy equal -${up}*xy
+variable deltaxz equal -${up}*xz
+variable deltayz equal -${up}*yz
+
+if "${dir} == 1" then &
+   "change_box all x delta 0 ${delta} remap units box"
+if "${dir} == 2" then &
+   "change_box all y delta 0 ${delta} remap units box"
+if "${dir} == 3" then &
+   "change_box all z delta 0 ${delta} remap units box"
+if "${dir} == 4" then &
+   "change_box all yz delta ${delta} remap units box"
+if "${dir} == 5" then &
+   "change_box all xz delta ${delta} remap units box"
+if "${dir} == 6" then &
+   "change_box all xy delta ${delta} remap units box"
+
+minimize ${etol} ${ftol} ${maxiter} ${maxeval}
+`
@@ -0,0 +1,108 @@
+package initgen
+
+import "fmt"
+
+// UnitSystem is one of the LAMMPS `units` styles. Only the six systems below
+// are exposed, and ResolveUnitBlock rejects lj; see the LAMMPS `units`
+// command reference for the full base-unit tables.
+type UnitSystem string
+
+const (
+	UnitLJ       UnitSystem = "lj"
+	UnitReal     UnitSystem = "real"
+	UnitMetal    UnitSystem = "metal"
+	UnitSI       UnitSystem = "si"
+	UnitCGS      UnitSystem = "cgs"
+	UnitElectron UnitSystem = "electron"
+)
+
+// unitConversion holds, for a given LAMMPS unit system, the native pressure
+// unit produced by `compute pressure` and the cfac/cunits pair init.mod
+// must emit to convert that into the requested output unit.
+type unitConversion struct {
+	nativePressureUnit string
+	// cfacTo maps a requested output unit to the conversion factor applied
+	// to the native pressure value.
+	cfacTo map[string]float64
+}
+
+var conversions = map[UnitSystem]unitConversion{
+	UnitMetal: {
+		nativePressureUnit: "bar",
+		cfacTo: map[string]float64{
+			"GPa": 1.0e-4,
+			"bar": 1.0,
+		},
+	},
+	UnitReal: {
+		nativePressureUnit: "atm",
+		cfacTo: map[string]float64{
+			"GPa": 1.01325e-4,
+			"bar": 1.01325,
+		},
+	},
+	UnitSI: {
+		nativePressureUnit: "Pa",
+		cfacTo: map[string]float64{
+			"GPa": 1.0e-9,
+			"bar": 1.0e-5,
+		},
+	},
+	UnitCGS: {
+		nativePressureUnit: "dyne/cm^2",
+		cfacTo: map[string]float64{
+			"GPa": 1.0e-10,
+			"bar": 1.0e-6,
+		},
+	},
+	UnitElectron: {
+		nativePressureUnit: "Pa",
+		cfacTo: map[string]float64{
+			"GPa": 1.0e-9,
+			"bar": 1.0e-5,
+		},
+	},
+	// UnitLJ deliberately has no entries: LJ pressure is reduced/dimensionless
+	// and cannot be converted to a physical unit such as GPa.
+}
+
+// UnitBlock is the rendered `units`/`cfac`/`cunits` fragment for init.mod.
+type UnitBlock struct {
+	UnitsLine string
+	Cfac      float64
+	Cunits    string
+}
+
+// ResolveUnitBlock validates the (units, output) combination and returns the
+// init.mod fragment for it. displace.mod's stress post-processing only
+// understands scaling a native pressure by a scalar cfac into cunits, so
+// any combination that cannot be expressed that way is rejected rather than
+// silently defaulting to metal/GPa.
+func ResolveUnitBlock(sys UnitSystem, outputUnit string) (UnitBlock, error) {
+	if sys == UnitLJ {
+		return UnitBlock{}, fmt.Errorf("initgen: unit system %q has no physical pressure scale; "+
+			"displace.mod cannot produce %q output", sys, outputUnit)
+	}
+	conv, ok := conversions[sys]
+	if !ok {
+		return UnitBlock{}, fmt.Errorf("initgen: unsupported unit system %q", sys)
+	}
+	cfac, ok := conv.cfacTo[outputUnit]
+	if !ok {
+		return UnitBlock{}, fmt.Errorf("initgen: unit system %q cannot produce output unit %q (supported: %v)",
+			sys, outputUnit, supportedOutputs(conv))
+	}
+	return UnitBlock{
+		UnitsLine: fmt.Sprintf("units           %s", sys),
+		Cfac:      cfac,
+		Cunits:    outputUnit,
+	}, nil
+}
+
+func supportedOutputs(conv unitConversion) []string {
+	outs := make([]string, 0, len(conv.cfacTo))
+	for u := range conv.cfacTo {
+		outs = append(outs, u)
+	}
+	return outs
+}
@@ -0,0 +1,44 @@
+package initgen
+
+import "testing"
+
+func TestResolveUnitBlock(t *testing.T) {
+	cases := []struct {
+		name       string
+		sys        UnitSystem
+		outputUnit string
+		wantCfac   float64
+		wantErr    bool
+	}{
+		{name: "metal to GPa", sys: UnitMetal, outputUnit: "GPa", wantCfac: 1.0e-4},
+		{name: "metal to bar", sys: UnitMetal, outputUnit: "bar", wantCfac: 1.0},
+		{name: "real to GPa", sys: UnitReal, outputUnit: "GPa", wantCfac: 1.01325e-4},
+		{name: "si to GPa", sys: UnitSI, outputUnit: "GPa", wantCfac: 1.0e-9},
+		{name: "cgs to GPa", sys: UnitCGS, outputUnit: "GPa", wantCfac: 1.0e-10},
+		{name: "electron to GPa", sys: UnitElectron, outputUnit: "GPa", wantCfac: 1.0e-9},
+		{name: "lj rejected regardless of output unit", sys: UnitLJ, outputUnit: "GPa", wantErr: true},
+		{name: "unsupported output unit rejected", sys: UnitMetal, outputUnit: "psi", wantErr: true},
+		{name: "unknown unit system rejected", sys: UnitSystem("bogus"), outputUnit: "GPa", wantErr: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			block, err := ResolveUnitBlock(tc.sys, tc.outputUnit)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("ResolveUnitBlock(%q, %q) = %+v, want error", tc.sys, tc.outputUnit, block)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ResolveUnitBlock(%q, %q) returned unexpected error: %v", tc.sys, tc.outputUnit, err)
+			}
+			if block.Cfac != tc.wantCfac {
+				t.Errorf("Cfac = %g, want %g", block.Cfac, tc.wantCfac)
+			}
+			if block.Cunits != tc.outputUnit {
+				t.Errorf("Cunits = %q, want %q", block.Cunits, tc.outputUnit)
+			}
+		})
+	}
+}
@@ -0,0 +1,319 @@
+// Package elastic holds the shared scaffolding for the elastic-constants
+// workflow: generating inputs (see elastic/initgen) and validating them
+// before LAMMPS ever sees them.
+package elastic
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// Potential is the minimal description of an interatomic potential needed
+// to validate a structure against it: its cutoff radius, used to reject
+// boxes that are too small to be periodic-image-safe.
+type Potential struct {
+	Style string
+	Rcut  float64
+}
+
+// box is the subset of a LAMMPS data file header this package cares about.
+type box struct {
+	xlo, xhi   float64
+	ylo, yhi   float64
+	zlo, zhi   float64
+	xy, xz, yz float64
+	hasTilt    bool
+}
+
+// Report is the outcome of PreflightData: either the data file passed as-is,
+// was auto-repaired, or failed with specific diagnostics.
+type Report struct {
+	// Issues lists every problem found, even ones that were auto-repaired.
+	Issues []string
+	// Repaired is true if PreflightData rewrote path (via Atomsk, or by
+	// rewriting its tilt line directly) to fix a problem rather than aborting.
+	Repaired bool
+}
+
+// PreflightOptions controls PreflightData's behavior.
+type PreflightOptions struct {
+	// Boundary is the `boundary` setting the caller intends to pass to
+	// LAMMPS (e.g. "p p p"). The data file itself does not record this, so
+	// it must be supplied by the caller. Defaults to "p p p", the setting
+	// every init.mod in this module uses.
+	Boundary string
+	// RequireTriclinic requires tilt factors to be present because xy/xz/yz
+	// deformation will be applied downstream (as in displace.mod). It is true
+	// when PreflightData is called without a PreflightOptions; a supplied
+	// PreflightOptions is used as given, so there it is false unless set.
+	RequireTriclinic bool
+	// AutoRepair calls out to Atomsk to fix a non-triclinic or
+	// too-small box, and rewrites out-of-range tilt factors in place,
+	// instead of aborting. Defaults to false (abort).
+	AutoRepair bool
+}
+
+// PreflightData parses the LAMMPS data file at path and verifies it is safe
+// to read_data into the elastic-constants workflow: boundaries are fully
+// periodic, tilt factors respect LAMMPS's |xy| <= (xhi-xlo)/2 constraint (and
+// likewise for xz, yz), no box dimension is shorter than 2*pot.Rcut, and (if
+// required) the box is genuinely triclinic. On failure it either auto-repairs
+// (when opts.AutoRepair is set: out-of-range tilt factors are reduced in the
+// header, a too-small or non-triclinic box goes through Atomsk, and a
+// non-periodic boundary is never repaired but returned as an error) or
+// returns an error describing exactly which check failed.
+func PreflightData(path string, pot Potential, opts ...PreflightOptions) (Report, error) {
+	o := PreflightOptions{Boundary: "p p p", RequireTriclinic: true}
+	if len(opts) > 0 {
+		o = opts[0]
+		if o.Boundary == "" {
+			o.Boundary = "p p p"
+		}
+	}
+
+	b, err := parseDataBox(path)
+	if err != nil {
+		return Report{}, fmt.Errorf("elastic: preflight: %w", err)
+	}
+
+	boundaryIssue := checkBoundary(o.Boundary)
+	tiltRatioIssue := checkTiltConstraint(b)
+	minDimIssue := checkMinDimension(b, pot)
+	needsTriclinicRepair := o.RequireTriclinic && !b.hasTilt
+	var triclinicIssue string
+	if needsTriclinicRepair {
+		triclinicIssue = "box has no tilt factors but xy/xz/yz deformation is required"
+	}
+
+	var report Report
+	for _, issue := range []string{boundaryIssue, tiltRatioIssue, minDimIssue, triclinicIssue} {
+		if issue != "" {
+			report.Issues = append(report.Issues, issue)
+		}
+	}
+
+	if len(report.Issues) == 0 {
+		return report, nil
+	}
+
+	if !o.AutoRepair {
+		return report, fmt.Errorf("elastic: preflight failed for %s: %s", path, strings.Join(report.Issues, "; "))
+	}
+
+	// boundary is a LAMMPS run-time setting, not data-file content, so no
+	// rewrite of path can ever fix it; never report success over it.
+	if boundaryIssue != "" {
+		return report, fmt.Errorf("elastic: preflight auto-repair cannot fix boundary for %s: %s", path, boundaryIssue)
+	}
+
+	if tiltRatioIssue != "" {
+		if err := repairTiltRatio(path, b); err != nil {
+			return report, fmt.Errorf("elastic: preflight auto-repair failed for %s: %w", path, err)
+		}
+	}
+
+	if minDimIssue != "" || needsTriclinicRepair {
+		if err := repairWithAtomsk(path, needsTriclinicRepair); err != nil {
+			return report, fmt.Errorf("elastic: preflight auto-repair failed for %s: %w", path, err)
+		}
+	}
+
+	repaired, err := parseDataBox(path)
+	if err != nil {
+		return report, fmt.Errorf("elastic: preflight: re-validate %s: %w", path, err)
+	}
+	var remaining []string
+	for _, issue := range []string{checkTiltConstraint(repaired), checkMinDimension(repaired, pot)} {
+		if issue != "" {
+			remaining = append(remaining, issue)
+		}
+	}
+	if o.RequireTriclinic && !repaired.hasTilt {
+		remaining = append(remaining, "box has no tilt factors but xy/xz/yz deformation is required")
+	}
+	if len(remaining) > 0 {
+		return report, fmt.Errorf("elastic: preflight auto-repair left issues unresolved for %s: %s", path, strings.Join(remaining, "; "))
+	}
+
+	report.Repaired = true
+	return report, nil
+}
+
+func checkBoundary(boundary string) string {
+	fields := strings.Fields(boundary)
+	if len(fields) != 3 {
+		return fmt.Sprintf("boundary %q must specify exactly 3 directions", boundary)
+	}
+	for i, f := range fields {
+		if f != "p" {
+			return fmt.Sprintf("boundary direction %d (%q) is not periodic; elastic-constants deformation requires p p p", i, f)
+		}
+	}
+	return ""
+}
+
+// checkTiltConstraint enforces LAMMPS's requirement that each tilt factor
+// not exceed half the corresponding box length, which otherwise triggers the
+// box-collapse failure mode.
+func checkTiltConstraint(b box) string {
+	lx := b.xhi - b.xlo
+	ly := b.yhi - b.ylo
+	if !b.hasTilt {
+		return ""
+	}
+	var bad []string
+	if abs(b.xy) > lx/2 {
+		bad = append(bad, fmt.Sprintf("xy=%g exceeds (xhi-xlo)/2=%g", b.xy, lx/2))
+	}
+	if abs(b.xz) > lx/2 {
+		bad = append(bad, fmt.Sprintf("xz=%g exceeds (xhi-xlo)/2=%g", b.xz, lx/2))
+	}
+	if abs(b.yz) > ly/2 {
+		bad = append(bad, fmt.Sprintf("yz=%g exceeds (yhi-ylo)/2=%g", b.yz, ly/2))
+	}
+	if len(bad) == 0 {
+		return ""
+	}
+	return "tilt factor constraint violated: " + strings.Join(bad, ", ")
+}
+
+func checkMinDimension(b box, pot Potential) string {
+	min := 2 * pot.Rcut
+	if min <= 0 {
+		return ""
+	}
+	dims := map[string]float64{"x": b.xhi - b.xlo, "y": b.yhi - b.ylo, "z": b.zhi - b.zlo}
+	var bad []string
+	for name, len := range dims {
+		if len < min {
+			bad = append(bad, fmt.Sprintf("%s=%g < 2*rcut=%g", name, len, min))
+		}
+	}
+	if len(bad) == 0 {
+		return ""
+	}
+	return "box dimension smaller than 2*rcut for " + pot.Style + ": " + strings.Join(bad, ", ")
+}
+
+// repairWithAtomsk calls out to Atomsk to duplicate the cell 2x2x2 (once,
+// whichever problem triggered the repair; PreflightData re-validates the
+// result) and, when needsTriclinic is set, to introduce a small xy shear so
+// the box gains the tilt factors a later xy/xz/yz deformation needs. A small shear, not
+// "-orthogonal-box" (which forces the box to stay orthogonal and would
+// actively undo the repair), is what turns an orthogonal cell triclinic.
+func repairWithAtomsk(path string, needsTriclinic bool) error {
+	args := []string{path}
+	if needsTriclinic {
+		args = append(args, "-shear", "xy", "0.01")
+	}
+	args = append(args, "-duplicate", "2", "2", "2", "lmp", "-prefix", path)
+	out, err := exec.Command("atomsk", args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("atomsk: %w: %s", err, out)
+	}
+	return nil
+}
+
+// repairTiltRatio reduces each tilt factor to satisfy LAMMPS's
+// |xy| <= (xhi-xlo)/2 (and likewise for xz, yz) constraint by subtracting the
+// box length the lattice is periodic under: the same triclinic-equivalent
+// "box flip" transform `change_box ... remap` performs at run time. Unlike
+// the too-small-box or missing-tilt cases, this cannot be fixed by any
+// Atomsk cell transform (duplicating or shearing only scales the existing
+// ratio), so it is done directly on the data file's header.
+func repairTiltRatio(path string, b box) error {
+	lx := b.xhi - b.xlo
+	ly := b.yhi - b.ylo
+	// Subtracting k periods of the b-vector (xy, ly, 0) from the c-vector
+	// changes yz by k*ly and xz by k*xy, so xz is shifted before its own
+	// reduction.
+	yz := reduceTilt(b.yz, ly)
+	xz := b.xz
+	if ly != 0 {
+		xz -= math.Round(b.yz/ly) * b.xy
+	}
+	xz = reduceTilt(xz, lx)
+	xy := reduceTilt(b.xy, lx)
+	return rewriteTiltLine(path, xy, xz, yz)
+}
+
+// reduceTilt subtracts the nearest multiple of length from tilt so the
+// result satisfies |tilt| <= length/2; this is the same lattice-equivalent
+// cell the flipped box describes, just expressed with a smaller tilt.
+func reduceTilt(tilt, length float64) float64 {
+	if length == 0 {
+		return tilt
+	}
+	return tilt - math.Round(tilt/length)*length
+}
+
+// rewriteTiltLine replaces the "xy xz yz" header line of the LAMMPS data
+// file at path with the given values, leaving every other line untouched.
+func rewriteTiltLine(path string, xy, xz, yz float64) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	var lines []string
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line := sc.Text()
+		if strings.HasSuffix(strings.TrimSpace(line), "xy xz yz") {
+			line = fmt.Sprintf("%g %g %g xy xz yz", xy, xz, yz)
+		}
+		lines = append(lines, line)
+	}
+	f.Close()
+	if err := sc.Err(); err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0o644)
+}
+
+// parseDataBox reads just the box-bounds header of a LAMMPS data file: the
+// xlo/xhi, ylo/yhi, zlo/zhi lines and, if present, the "xy xz yz" tilt line.
+func parseDataBox(path string) (box, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return box{}, err
+	}
+	defer f.Close()
+
+	var b box
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		fields := strings.Fields(line)
+		switch {
+		case strings.HasSuffix(line, "xlo xhi"):
+			b.xlo, b.xhi = mustFloat(fields[0]), mustFloat(fields[1])
+		case strings.HasSuffix(line, "ylo yhi"):
+			b.ylo, b.yhi = mustFloat(fields[0]), mustFloat(fields[1])
+		case strings.HasSuffix(line, "zlo zhi"):
+			b.zlo, b.zhi = mustFloat(fields[0]), mustFloat(fields[1])
+		case strings.HasSuffix(line, "xy xz yz"):
+			b.xy, b.xz, b.yz = mustFloat(fields[0]), mustFloat(fields[1]), mustFloat(fields[2])
+			b.hasTilt = true
+		case strings.Contains(line, "Atoms"):
+			return b, sc.Err()
+		}
+	}
+	return b, sc.Err()
+}
+
+func mustFloat(s string) float64 {
+	v, _ := strconv.ParseFloat(s, 64)
+	return v
+}
+
+func abs(v float64) float64 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
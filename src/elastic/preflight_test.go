@@ -0,0 +1,67 @@
+package elastic
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeDataFile builds a minimal LAMMPS data file header exercising just the
+// box-bounds lines parseDataBox reads.
+func writeDataFile(t *testing.T, xy, xz, yz float64) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "structure.data")
+	content := fmt.Sprintf(`LAMMPS data file
+
+4 atoms
+1 atom types
+
+0.0 10.0 xlo xhi
+0.0 10.0 ylo yhi
+0.0 10.0 zlo zhi
+%g %g %g xy xz yz
+
+Atoms
+
+1 1 0.0 0.0 0.0
+`, xy, xz, yz)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write data file: %v", err)
+	}
+	return path
+}
+
+func TestPreflightDataAutoRepairsExcessTiltRatio(t *testing.T) {
+	// xy=8 exceeds (xhi-xlo)/2=5 but the box is already triclinic, so only
+	// a header rewrite (not a fresh Atomsk shear/duplicate) can fix it.
+	path := writeDataFile(t, 8, 0, 0)
+
+	report, err := PreflightData(path, Potential{Style: "eam/alloy", Rcut: 1}, PreflightOptions{AutoRepair: true})
+	if err != nil {
+		t.Fatalf("PreflightData() error = %v, want nil after auto-repair", err)
+	}
+	if !report.Repaired {
+		t.Errorf("report.Repaired = false, want true")
+	}
+
+	repaired, err := parseDataBox(path)
+	if err != nil {
+		t.Fatalf("parseDataBox() after repair error = %v", err)
+	}
+	if issue := checkTiltConstraint(repaired); issue != "" {
+		t.Errorf("tilt constraint still violated after auto-repair: %s", issue)
+	}
+}
+
+func TestPreflightDataAutoRepairCannotFixBoundary(t *testing.T) {
+	path := writeDataFile(t, 1, 0, 0)
+
+	_, err := PreflightData(path, Potential{Style: "eam/alloy", Rcut: 1}, PreflightOptions{
+		Boundary:   "p p f",
+		AutoRepair: true,
+	})
+	if err == nil {
+		t.Fatal("PreflightData() with non-periodic boundary and AutoRepair = true, want error, got nil")
+	}
+}
@@ -0,0 +1,401 @@
+// Package sweep fans out elastic-constants jobs across a cross product of
+// structures (Materials Project IDs or local CIFs) and potentials (from the
+// NIST Interatomic Potentials Repository), collecting the resulting C_ij
+// tensors and derived moduli into a single results table.
+package sweep
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/ANL-NST/LAMMPS-Agents/src/elastic"
+	"github.com/ANL-NST/LAMMPS-Agents/src/elastic/initgen"
+)
+
+// PotentialFamily is one of the interatomic potential styles the NIST IPR
+// hosts; it is also the LAMMPS pair_style used for the job.
+type PotentialFamily string
+
+const (
+	FamilyEAMAlloy PotentialFamily = "eam/alloy"
+	FamilyMEAM     PotentialFamily = "meam"
+	FamilyTersoff  PotentialFamily = "tersoff"
+	FamilySW       PotentialFamily = "sw"
+	FamilyReaxFF   PotentialFamily = "reaxff"
+	FamilyCOMB     PotentialFamily = "comb"
+	FamilyAIREBO   PotentialFamily = "airebo"
+)
+
+// Structure identifies one input cell, either by Materials Project ID (MP
+// resolution is left to the caller via LocalPath) or a local CIF/data file.
+type Structure struct {
+	// MPID is the Materials Project structure ID, e.g. "mp-81". When set, it
+	// names the job (JobResult.StructureID and the job's output directory) in
+	// place of LocalPath; the structure itself is always read from LocalPath.
+	MPID string
+	// LocalPath is the path to a CIF (or any initgen-supported) structure
+	// file already on disk.
+	LocalPath string
+}
+
+// Potential is one NIST IPR potential entry to sweep against every structure.
+type Potential struct {
+	Name           string
+	Family         PotentialFamily
+	Rcut           float64
+	PairCoeffLines []string
+}
+
+// Options controls RunSweep.
+type Options struct {
+	OutDir      string
+	Concurrency int
+	// LammpsBin is the LAMMPS executable each job invokes. Defaults to "lmp".
+	LammpsBin string
+	// Parquet, if set, additionally writes results in Parquet form to
+	// <OutDir>/results.parquet via the supplied ParquetWriter. The module
+	// does not vendor a Parquet encoder itself, so this is left nil (no
+	// Parquet output) unless the caller plugs one in (e.g. via parquet-go).
+	Parquet ParquetWriter
+}
+
+// JobResult is the outcome of one (structure, potential) job.
+type JobResult struct {
+	StructureID   string        `json:"structure_id"`
+	PotentialName string        `json:"potential_name"`
+	Cij           [6][6]float64 `json:"cij"`
+	Bulk          float64       `json:"bulk_modulus_gpa"`
+	Shear         float64       `json:"shear_modulus_gpa"`
+	Young         float64       `json:"young_modulus_gpa"`
+	ZenerAniso    float64       `json:"zener_anisotropy"`
+	CohesiveE     float64       `json:"cohesive_energy_ev"`
+	Flags         []string      `json:"flags,omitempty"`
+	Error         string        `json:"error,omitempty"`
+}
+
+// RunSweep runs one elastic-constants job per (structure, potential) pair and
+// returns every JobResult, including failed jobs (recorded via JobResult.Error
+// rather than aborting the whole sweep).
+func RunSweep(structures []Structure, potentials []Potential, opts Options) ([]JobResult, error) {
+	if opts.OutDir == "" {
+		opts.OutDir = "sweep_results"
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 4
+	}
+	if opts.LammpsBin == "" {
+		opts.LammpsBin = "lmp"
+	}
+	if err := os.MkdirAll(opts.OutDir, 0o755); err != nil {
+		return nil, fmt.Errorf("sweep: %w", err)
+	}
+
+	type job struct {
+		s Structure
+		p Potential
+	}
+	var jobs []job
+	for _, s := range structures {
+		for _, p := range potentials {
+			jobs = append(jobs, job{s, p})
+		}
+	}
+
+	results := make([]JobResult, len(jobs))
+	sem := make(chan struct{}, opts.Concurrency)
+	var wg sync.WaitGroup
+	for i, j := range jobs {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, j job) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i] = runJob(j.s, j.p, opts.OutDir, opts.LammpsBin)
+		}(i, j)
+	}
+	wg.Wait()
+
+	if err := WriteJSON(results, opts.OutDir+"/results.json"); err != nil {
+		return results, fmt.Errorf("sweep: %w", err)
+	}
+
+	if opts.Parquet != nil {
+		if err := opts.Parquet.WriteResults(results, opts.OutDir+"/results.parquet"); err != nil {
+			return results, fmt.Errorf("sweep: %w", err)
+		}
+	}
+
+	return results, nil
+}
+
+func structureID(s Structure) string {
+	if s.MPID != "" {
+		return s.MPID
+	}
+	return s.LocalPath
+}
+
+func runJob(s Structure, p Potential, outDir, lammpsBin string) JobResult {
+	id := structureID(s)
+	r := JobResult{StructureID: id, PotentialName: p.Name}
+
+	jobDir := fmt.Sprintf("%s/%s__%s", outDir, sanitize(id), sanitize(p.Name))
+	if err := os.MkdirAll(jobDir, 0o755); err != nil {
+		r.Error = err.Error()
+		return r
+	}
+
+	gen, err := initgen.GenerateInitMod(s.LocalPath, initgen.Options{OutDir: jobDir})
+	if err != nil {
+		r.Error = fmt.Sprintf("initgen: %v", err)
+		return r
+	}
+
+	if _, err := elastic.PreflightData(gen.DataFile, elastic.Potential{Style: string(p.Family), Rcut: p.Rcut}); err != nil {
+		r.Error = fmt.Sprintf("preflight: %v", err)
+		return r
+	}
+
+	cij, cohesive, err := runElasticJob(gen, p, jobDir, lammpsBin)
+	if err != nil {
+		r.Error = fmt.Sprintf("run: %v", err)
+		return r
+	}
+
+	r.Cij = cij
+	r.CohesiveE = cohesive
+	r.Bulk, r.Shear, r.Young, r.ZenerAniso = voigtModuli(cij)
+
+	if !isPositiveDefinite(cij) {
+		r.Flags = append(r.Flags, "cij_not_positive_definite")
+	}
+	if cohesive > 0 {
+		r.Flags = append(r.Flags, "unphysical_cohesive_energy")
+	}
+	return r
+}
+
+// runElasticJob fills in gen.PotentialMod with p's pair_style/pair_coeff,
+// drives LAMMPS through the six finite deformations in gen.DisplaceMod (one
+// read_restart per direction, mirroring the stock LAMMPS
+// examples/elastic/in.elastic post-processing so each deformation starts
+// from the same relaxed reference state), and parses the resulting `print`
+// output for the 6x6 stiffness tensor and cohesive energy.
+func runElasticJob(gen initgen.Result, p Potential, jobDir, lammpsBin string) (cij [6][6]float64, cohesive float64, err error) {
+	potContent := renderJobPotentialMod(p)
+	if err := os.WriteFile(gen.PotentialMod, []byte(potContent), 0o644); err != nil {
+		return cij, 0, fmt.Errorf("runElasticJob: write potential.mod: %w", err)
+	}
+
+	scriptPath := filepath.Join(jobDir, "elastic.in")
+	if err := os.WriteFile(scriptPath, []byte(renderElasticScript(gen.InitMod, gen.PotentialMod, gen.DisplaceMod)), 0o644); err != nil {
+		return cij, 0, fmt.Errorf("runElasticJob: write elastic.in: %w", err)
+	}
+
+	cmd := exec.Command(lammpsBin, "-in", scriptPath)
+	cmd.Dir = jobDir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return cij, 0, fmt.Errorf("runElasticJob: %s: %w: %s", lammpsBin, err, out)
+	}
+
+	return parseElasticOutput(string(out))
+}
+
+// renderJobPotentialMod fills in the potential.mod placeholder initgen wrote
+// with the pair_style/pair_coeff this job is actually sweeping.
+func renderJobPotentialMod(p Potential) string {
+	var sb strings.Builder
+	sb.WriteString("# potential.mod – interatomic potential definition (elastic/sweep job)\n\n")
+	fmt.Fprintf(&sb, "pair_style      %s\n", p.Family)
+	if len(p.PairCoeffLines) == 0 {
+		sb.WriteString("pair_coeff      * *\n")
+	} else {
+		for _, line := range p.PairCoeffLines {
+			sb.WriteString(line)
+			sb.WriteString("\n")
+		}
+	}
+	sb.WriteString("\nneighbor 1.0 bin\nneigh_modify once no every 1 delay 0 check yes\n")
+	return sb.String()
+}
+
+// renderElasticScript is the top-level driver that ties init.mod/potential.mod
+// (struct- and potential-specific) to displace.mod (shared, see initgen),
+// following the same read_restart-per-direction structure as the stock
+// LAMMPS examples/elastic/in.elastic: relax once, snapshot the reference
+// lengths/stresses, then for each of the six strain directions restore that
+// reference state before applying the deformation so every column of the
+// stiffness tensor is measured from the same undeformed cell.
+func renderElasticScript(initMod, potentialMod, displaceMod string) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, `# elastic.in generated by elastic/sweep, mirroring LAMMPS examples/elastic/in.elastic
+include         %s
+include         %s
+
+fix             3 all box/relax iso 0.0
+min_style       cg
+minimize        ${etol} ${ftol} ${maxiter} ${maxeval}
+unfix           3
+
+variable        tmp equal pe
+variable        ecoh equal ${tmp}/count(all)
+
+variable        lx0 equal $(lx)
+variable        ly0 equal $(ly)
+variable        lz0 equal $(lz)
+
+variable        pxx0 equal $(pxx)
+variable        pyy0 equal $(pyy)
+variable        pzz0 equal $(pzz)
+variable        pyz0 equal $(pyz)
+variable        pxz0 equal $(pxz)
+variable        pxy0 equal $(pxy)
+
+write_restart   restart.equil
+
+print           "ECOH ${ecoh}"
+`, initMod, potentialMod)
+
+	for dir := 1; dir <= 6; dir++ {
+		fmt.Fprintf(&sb, `
+clear
+read_restart    restart.equil
+include         %s
+variable        dir equal %d
+include         %s
+print           "CIJ 1 %d $(-(pxx-v_pxx0)/(v_delta/v_len0)*v_cfac)"
+print           "CIJ 2 %d $(-(pyy-v_pyy0)/(v_delta/v_len0)*v_cfac)"
+print           "CIJ 3 %d $(-(pzz-v_pzz0)/(v_delta/v_len0)*v_cfac)"
+print           "CIJ 4 %d $(-(pyz-v_pyz0)/(v_delta/v_len0)*v_cfac)"
+print           "CIJ 5 %d $(-(pxz-v_pxz0)/(v_delta/v_len0)*v_cfac)"
+print           "CIJ 6 %d $(-(pxy-v_pxy0)/(v_delta/v_len0)*v_cfac)"
+`, potentialMod, dir, displaceMod, dir, dir, dir, dir, dir, dir)
+	}
+	return sb.String()
+}
+
+// parseElasticOutput scans a LAMMPS run's combined output for the "ECOH" and
+// "CIJ <row> <col> <value>" lines printed by renderElasticScript, requiring
+// all 36 Cij entries to be present before returning success.
+func parseElasticOutput(output string) (cij [6][6]float64, cohesive float64, err error) {
+	found := 0
+	sc := bufio.NewScanner(strings.NewReader(output))
+	for sc.Scan() {
+		fields := strings.Fields(strings.TrimSpace(sc.Text()))
+		switch {
+		case len(fields) == 2 && fields[0] == "ECOH":
+			cohesive, err = strconv.ParseFloat(fields[1], 64)
+			if err != nil {
+				return cij, 0, fmt.Errorf("parse ECOH: %w", err)
+			}
+		case len(fields) == 4 && fields[0] == "CIJ":
+			row, errRow := strconv.Atoi(fields[1])
+			col, errCol := strconv.Atoi(fields[2])
+			val, errVal := strconv.ParseFloat(fields[3], 64)
+			if errRow != nil || errCol != nil || errVal != nil || row < 1 || row > 6 || col < 1 || col > 6 {
+				return cij, 0, fmt.Errorf("parse CIJ line %q", sc.Text())
+			}
+			cij[row-1][col-1] = val
+			found++
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return cij, 0, fmt.Errorf("scan LAMMPS output: %w", err)
+	}
+	if found != 36 {
+		return cij, 0, fmt.Errorf("expected 36 Cij entries in LAMMPS output, found %d", found)
+	}
+	return cij, cohesive, nil
+}
+
+// voigtModuli computes the Voigt-averaged bulk and shear moduli, Young's
+// modulus, and the Zener anisotropy ratio from a (possibly non-cubic) 6x6
+// stiffness tensor given in GPa.
+func voigtModuli(c [6][6]float64) (bulk, shear, young, zener float64) {
+	bulk = (c[0][0] + c[1][1] + c[2][2] + 2*(c[0][1]+c[1][2]+c[0][2])) / 9
+	shear = (c[0][0] + c[1][1] + c[2][2] - (c[0][1] + c[1][2] + c[0][2]) + 3*(c[3][3]+c[4][4]+c[5][5])) / 15
+	if 3*bulk+shear != 0 {
+		young = 9 * bulk * shear / (3*bulk + shear)
+	}
+	if c[0][0]-c[0][1] != 0 {
+		zener = 2 * c[3][3] / (c[0][0] - c[0][1])
+	}
+	return bulk, shear, young, zener
+}
+
+// isPositiveDefinite checks the leading-principal-minor (Sylvester)
+// criterion on the 6x6 Voigt stiffness tensor.
+func isPositiveDefinite(c [6][6]float64) bool {
+	for n := 1; n <= 6; n++ {
+		if det(c, n) <= 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// det computes the determinant of the leading n x n submatrix of c via
+// Gaussian elimination.
+func det(c [6][6]float64, n int) float64 {
+	var m [6][6]float64
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			m[i][j] = c[i][j]
+		}
+	}
+	result := 1.0
+	for i := 0; i < n; i++ {
+		pivot := m[i][i]
+		if pivot == 0 {
+			return 0
+		}
+		result *= pivot
+		for k := i + 1; k < n; k++ {
+			factor := m[k][i] / pivot
+			for j := i; j < n; j++ {
+				m[k][j] -= factor * m[i][j]
+			}
+		}
+	}
+	return result
+}
+
+func sanitize(s string) string {
+	out := make([]rune, 0, len(s))
+	for _, r := range s {
+		if r == '/' || r == ' ' {
+			out = append(out, '_')
+			continue
+		}
+		out = append(out, r)
+	}
+	return string(out)
+}
+
+// WriteJSON writes results as a JSON array to path.
+func WriteJSON(results []JobResult, path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	return enc.Encode(results)
+}
+
+// ParquetWriter writes results in Parquet form. Plug an implementation into
+// Options.Parquet to have RunSweep call it after WriteJSON succeeds; the
+// sweep itself only depends on this interface so the module does not need to
+// vendor a Parquet encoder (e.g. via parquet-go).
+type ParquetWriter interface {
+	WriteResults(results []JobResult, path string) error
+}
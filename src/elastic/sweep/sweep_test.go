@@ -0,0 +1,95 @@
+package sweep
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// cubicCij builds a Voigt stiffness tensor for a cubic crystal from its
+// three independent constants (c11, c12, c44), e.g. FCC Al-like values.
+func cubicCij(c11, c12, c44 float64) [6][6]float64 {
+	var c [6][6]float64
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			if i == j {
+				c[i][j] = c11
+			} else {
+				c[i][j] = c12
+			}
+		}
+	}
+	for i := 3; i < 6; i++ {
+		c[i][i] = c44
+	}
+	return c
+}
+
+func TestVoigtModuliCubic(t *testing.T) {
+	// Approximate FCC Al elastic constants (GPa).
+	c := cubicCij(107, 61, 28)
+	bulk, shear, young, zener := voigtModuli(c)
+
+	wantBulk := (107.0 + 107.0 + 107.0 + 2*(61+61+61)) / 9
+	if bulk != wantBulk {
+		t.Errorf("bulk = %g, want %g", bulk, wantBulk)
+	}
+	if shear <= 0 {
+		t.Errorf("shear = %g, want > 0 for a physically stable cubic crystal", shear)
+	}
+	if young <= 0 {
+		t.Errorf("young = %g, want > 0", young)
+	}
+	wantZener := 2 * 28.0 / (107.0 - 61.0)
+	if zener != wantZener {
+		t.Errorf("zener = %g, want %g", zener, wantZener)
+	}
+}
+
+func TestParseElasticOutput(t *testing.T) {
+	var out strings.Builder
+	out.WriteString("LAMMPS (2 Aug 2023)\n")
+	out.WriteString(`print           "ECOH -3.36"` + "\n")
+	out.WriteString("ECOH -3.36\n")
+	for dir := 1; dir <= 6; dir++ {
+		for row := 1; row <= 6; row++ {
+			fmt.Fprintf(&out, "CIJ %d %d %g\n", row, dir, float64(row*10+dir))
+		}
+	}
+
+	cij, cohesive, err := parseElasticOutput(out.String())
+	if err != nil {
+		t.Fatalf("parseElasticOutput() error = %v", err)
+	}
+	if cohesive != -3.36 {
+		t.Errorf("cohesive = %g, want -3.36", cohesive)
+	}
+	if cij[0][0] != 11 || cij[5][5] != 66 || cij[2][4] != 35 {
+		t.Errorf("cij = %+v, unexpected values", cij)
+	}
+}
+
+func TestParseElasticOutputMissingEntries(t *testing.T) {
+	if _, _, err := parseElasticOutput("ECOH -3.36\nCIJ 1 1 107\n"); err == nil {
+		t.Fatal("parseElasticOutput() with only 1 of 36 Cij entries, want error")
+	}
+}
+
+func TestIsPositiveDefinite(t *testing.T) {
+	cases := []struct {
+		name string
+		c    [6][6]float64
+		want bool
+	}{
+		{name: "stable cubic crystal", c: cubicCij(107, 61, 28), want: true},
+		{name: "zero tensor is not positive definite", c: [6][6]float64{}, want: false},
+		{name: "negative diagonal is not positive definite", c: cubicCij(-10, 5, 5), want: false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := isPositiveDefinite(tc.c); got != tc.want {
+				t.Errorf("isPositiveDefinite() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
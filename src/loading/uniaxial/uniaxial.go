@@ -0,0 +1,249 @@
+// Package uniaxial drives finite-strain-rate uniaxial tension/compression
+// simulations on top of the same read_data/minimization/jiggle scaffolding
+// used by the elastic-constants workflow (see elastic/initgen), and parses
+// the resulting stress-strain log for dislocation-nucleation analysis.
+package uniaxial
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/ANL-NST/LAMMPS-Agents/src/elastic/initgen"
+)
+
+// Direction is a crystallographic loading axis.
+type Direction string
+
+const (
+	Direction100 Direction = "100"
+	Direction110 Direction = "110"
+	Direction111 Direction = "111"
+)
+
+// Mode selects whether the applied strain rate is tensile or compressive.
+type Mode string
+
+const (
+	ModeTension     Mode = "tension"
+	ModeCompression Mode = "compression"
+)
+
+// Options configures RunUniaxial.
+type Options struct {
+	// Units/OutputUnit are forwarded to initgen.GenerateInitMod so the
+	// stress log is reported in a consistent pressure unit.
+	Units      initgen.UnitSystem
+	OutputUnit string
+	// OutDir is where the generated LAMMPS input and log are written.
+	// Defaults to the directory containing structPath.
+	OutDir string
+	// LammpsBin is the LAMMPS executable to invoke. Defaults to "lmp".
+	LammpsBin string
+}
+
+// Result is the parsed outcome of a uniaxial run.
+type Result struct {
+	ScriptPath   string
+	LogPath      string
+	StressStrain []StressStrainPoint
+}
+
+// StressStrainPoint is one row of the engineering stress/strain/centrosymmetry
+// time series dumped during the run.
+type StressStrainPoint struct {
+	Step              int
+	Strain            float64
+	Stress            float64 // engineering stress, in OutputUnit
+	MaxCentrosymmetry float64
+}
+
+// RunUniaxial reuses the elastic-constants init.mod/potential.mod to set up
+// struct, then emits and drives a uniaxial tension/compression deformation
+// along direction at the given engineering strain rate, equilibrating
+// lateral boundaries at Tfinal/Pfinal with NPT.
+func RunUniaxial(structPath string, direction Direction, strainRate, Tfinal, Pfinal float64, mode Mode, opts Options) (Result, error) {
+	if opts.OutDir == "" {
+		opts.OutDir = filepath.Dir(structPath)
+	}
+	if opts.LammpsBin == "" {
+		opts.LammpsBin = "lmp"
+	}
+
+	orientedPath, err := orientStructure(structPath, direction, opts.OutDir)
+	if err != nil {
+		return Result{}, fmt.Errorf("uniaxial: %w", err)
+	}
+
+	gen, err := initgen.GenerateInitMod(orientedPath, initgen.Options{
+		OutDir:     opts.OutDir,
+		Units:      opts.Units,
+		OutputUnit: opts.OutputUnit,
+	})
+	if err != nil {
+		return Result{}, fmt.Errorf("uniaxial: %w", err)
+	}
+
+	signedRate := strainRate
+	if mode == ModeCompression {
+		signedRate = -strainRate
+	}
+
+	scriptPath := opts.OutDir + "/uniaxial.in"
+	logPath := opts.OutDir + "/uniaxial.log"
+	dumpPath := opts.OutDir + "/centrosymmetry.dump"
+	script := renderUniaxialScript(gen.InitMod, gen.PotentialMod, direction, signedRate, Tfinal, Pfinal, logPath, dumpPath)
+	if err := os.WriteFile(scriptPath, []byte(script), 0o644); err != nil {
+		return Result{}, fmt.Errorf("uniaxial: write script: %w", err)
+	}
+
+	if err := runLammps(opts.LammpsBin, scriptPath, opts.OutDir); err != nil {
+		return Result{}, fmt.Errorf("uniaxial: %w", err)
+	}
+
+	points, err := parseStressStrainLog(logPath)
+	if err != nil {
+		return Result{}, fmt.Errorf("uniaxial: parse log: %w", err)
+	}
+
+	return Result{ScriptPath: scriptPath, LogPath: logPath, StressStrain: points}, nil
+}
+
+// millerAxes returns the (x, y, z) Miller-index triplets an Atomsk -orient
+// pass rotates the cell onto, for each supported loading direction: x is the
+// tensile/compressive loading axis, y and z complete a right-handed
+// orthogonal set.
+func millerAxes(dir Direction) (x, y, z [3]int) {
+	switch dir {
+	case Direction100:
+		return [3]int{1, 0, 0}, [3]int{0, 1, 0}, [3]int{0, 0, 1}
+	case Direction110:
+		return [3]int{1, 1, 0}, [3]int{-1, 1, 0}, [3]int{0, 0, 1}
+	case Direction111:
+		return [3]int{1, 1, 1}, [3]int{-1, 2, -1}, [3]int{-1, 0, 1}
+	default:
+		return [3]int{1, 0, 0}, [3]int{0, 1, 0}, [3]int{0, 0, 1}
+	}
+}
+
+func millerString(v [3]int) string {
+	idx := func(n int) string {
+		if n < 0 {
+			return fmt.Sprintf("-%d", -n)
+		}
+		return fmt.Sprintf("%d", n)
+	}
+	return fmt.Sprintf("[%s%s%s]", idx(v[0]), idx(v[1]), idx(v[2]))
+}
+
+// orientStructure calls out to Atomsk to rotate structPath so its cell axes
+// (and hence the box x direction after read_data) align with direction,
+// writing the rotated structure into outDir for initgen to triclinic-ize.
+// Without this step, "direction" would have no effect on the simulated
+// geometry: LAMMPS reads atoms/box entirely from read_data, so a later
+// `lattice` declaration cannot reorient an already-loaded cell.
+func orientStructure(structPath string, dir Direction, outDir string) (string, error) {
+	x, y, z := millerAxes(dir)
+	outPath := filepath.Join(outDir, "oriented_"+string(dir)+".xsf")
+	cmd := exec.Command("atomsk", structPath, "-orient",
+		millerString(x), millerString(y), millerString(z),
+		"xsf", "-prefix", strings.TrimSuffix(outPath, ".xsf"))
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("atomsk -orient: %w: %s", err, out)
+	}
+	return outPath, nil
+}
+
+func renderUniaxialScript(initMod, potentialMod string, dir Direction, rate, Tfinal, Pfinal float64, logPath, dumpPath string) string {
+	return fmt.Sprintf(`# uniaxial.in – %s loading generated by loading/uniaxial
+# Structure was already rotated onto this axis by orientStructure (Atomsk
+# -orient) before read_data; x is now the loading direction.
+
+include %s
+include %s
+
+variable srate  equal %g
+variable tfinal equal %g
+variable pfinal equal %g
+
+fix             npt_lateral all npt temp ${tfinal} ${tfinal} 0.1 y ${pfinal} ${pfinal} 1.0 z ${pfinal} ${pfinal} 1.0
+
+compute         csym all centro/atom fcc
+compute         maxcsym all reduce max c_csym
+
+variable        lx0 equal $(lx)
+variable        strain equal "(lx - v_lx0)/v_lx0"
+variable        stress equal "-pxx*v_cfac"
+
+thermo_style    custom step v_strain v_stress c_maxcsym
+thermo          100
+
+dump            dcsym all custom 1000 %s id type x y z c_csym
+dump_modify     dcsym sort id
+
+fix             extension all deform 1 x erate ${srate} units box remap x
+
+log             %s
+run             200000
+`, dir, initMod, potentialMod, rate, Tfinal, Pfinal, dumpPath, logPath)
+}
+
+func runLammps(bin, scriptPath, dir string) error {
+	cmd := exec.Command(bin, "-in", scriptPath)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%s: %w: %s", bin, err, out)
+	}
+	return nil
+}
+
+// parseStressStrainLog reads the LAMMPS thermo log written by the
+// `thermo_style custom step v_strain v_stress c_maxcsym` line configured in
+// renderUniaxialScript.
+func parseStressStrainLog(path string) ([]StressStrainPoint, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var points []StressStrainPoint
+	inThermo := false
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		switch {
+		case strings.HasPrefix(line, "Step "):
+			inThermo = true
+			continue
+		case strings.HasPrefix(line, "Loop time"):
+			inThermo = false
+			continue
+		}
+		if !inThermo || line == "" {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 4 {
+			continue
+		}
+		step, err1 := strconv.Atoi(fields[0])
+		strain, err2 := strconv.ParseFloat(fields[1], 64)
+		stress, err3 := strconv.ParseFloat(fields[2], 64)
+		csym, err4 := strconv.ParseFloat(fields[3], 64)
+		if err1 != nil || err2 != nil || err3 != nil || err4 != nil {
+			continue
+		}
+		points = append(points, StressStrainPoint{Step: step, Strain: strain, Stress: stress, MaxCentrosymmetry: csym})
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return points, nil
+}
@@ -0,0 +1,60 @@
+package uniaxial
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseStressStrainLog(t *testing.T) {
+	log := `LAMMPS (2 Aug 2023)
+Step v_strain v_stress c_maxcsym
+0 0 0.1 0.01
+100 0.001 0.2 0.015
+200 0.002 0.30 0.02
+Loop time of 1.23 on 4 procs for 200 steps
+`
+	path := filepath.Join(t.TempDir(), "uniaxial.log")
+	if err := os.WriteFile(path, []byte(log), 0o644); err != nil {
+		t.Fatalf("write test log: %v", err)
+	}
+
+	points, err := parseStressStrainLog(path)
+	if err != nil {
+		t.Fatalf("parseStressStrainLog() error = %v", err)
+	}
+	want := []StressStrainPoint{
+		{Step: 0, Strain: 0, Stress: 0.1, MaxCentrosymmetry: 0.01},
+		{Step: 100, Strain: 0.001, Stress: 0.2, MaxCentrosymmetry: 0.015},
+		{Step: 200, Strain: 0.002, Stress: 0.30, MaxCentrosymmetry: 0.02},
+	}
+	if len(points) != len(want) {
+		t.Fatalf("got %d points, want %d: %+v", len(points), len(want), points)
+	}
+	for i, p := range points {
+		if p != want[i] {
+			t.Errorf("point %d = %+v, want %+v", i, p, want[i])
+		}
+	}
+}
+
+func TestParseStressStrainLogIgnoresTrailingOutput(t *testing.T) {
+	log := `Step v_strain v_stress c_maxcsym
+0 0 0.1 0.01
+Loop time of 0.5 on 1 procs for 1 steps
+
+Performance: 1.000 ns/day
+`
+	path := filepath.Join(t.TempDir(), "uniaxial.log")
+	if err := os.WriteFile(path, []byte(log), 0o644); err != nil {
+		t.Fatalf("write test log: %v", err)
+	}
+
+	points, err := parseStressStrainLog(path)
+	if err != nil {
+		t.Fatalf("parseStressStrainLog() error = %v", err)
+	}
+	if len(points) != 1 {
+		t.Fatalf("got %d points, want 1 (post-Loop output must not be parsed as thermo rows): %+v", len(points), points)
+	}
+}